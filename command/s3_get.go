@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/pail"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// s3get is the legacy single-object download command, kept alongside
+// s3Pull for projects that fetch one artifact at a time rather than a
+// whole task directory.
+type s3get struct {
+	RemoteFile string `mapstructure:"remote_file"`
+	LocalFile  string `mapstructure:"local_file"`
+
+	// StorageBackend selects which pail.Bucket implementation backs
+	// this command; unset means "s3" using conf.S3Data, as before.
+	StorageBackend string `mapstructure:"storage_backend"`
+
+	bucket pail.Bucket
+}
+
+func s3GetFactory() Command { return &s3get{} }
+
+func (c *s3get) Name() string { return "s3.get" }
+
+func (c *s3get) ParseParams(params map[string]interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{Result: c, WeaklyTypedInput: true})
+	if err != nil {
+		return errors.Wrap(err, "error constructing params decoder")
+	}
+	if err := decoder.Decode(params); err != nil {
+		return errors.Wrap(err, "error decoding s3.get params")
+	}
+	if c.RemoteFile == "" {
+		return errors.New("remote_file must not be empty")
+	}
+	if c.LocalFile == "" {
+		return errors.New("local_file must not be empty")
+	}
+	return nil
+}
+
+func (c *s3get) Execute(ctx context.Context, comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+	c.RemoteFile = conf.Expansions.ExpandString(c.RemoteFile)
+	c.LocalFile = conf.Expansions.ExpandString(c.LocalFile)
+
+	if c.bucket == nil {
+		bucket, err := newBucketForBackend(conf, c.StorageBackend)
+		if err != nil {
+			return errors.Wrap(err, "error constructing storage bucket")
+		}
+		c.bucket = bucket
+	}
+
+	reader, err := c.bucket.Get(ctx, c.RemoteFile)
+	if err != nil {
+		return errors.Wrapf(err, "error fetching '%s'", c.RemoteFile)
+	}
+	defer reader.Close()
+
+	if err := os.MkdirAll(filepath.Dir(c.LocalFile), 0777); err != nil {
+		return errors.WithStack(err)
+	}
+	out, err := os.Create(c.LocalFile)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, reader)
+	return errors.Wrap(err, "error writing local file")
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/evergreen-ci/pail"
+	"github.com/pkg/errors"
+)
+
+// s3ManifestName is the object written alongside a task's pushed artifacts
+// that records the paths, sizes, and digests s3Pull uses to verify and
+// resume a transfer.
+const s3ManifestName = "manifest.json"
+
+// s3ManifestEntry describes a single file written by s3Push.
+type s3ManifestEntry struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// s3Manifest is the companion object s3Push writes into a task's artifact
+// prefix so that s3Pull can verify and resume the transfer without
+// re-downloading files that are already correct on disk.
+type s3Manifest struct {
+	Files []s3ManifestEntry `json:"files"`
+}
+
+func manifestKeyForPrefix(remotePrefix string) string {
+	return remotePrefix + "/" + s3ManifestName
+}
+
+func buildManifest(localPaths map[string]string) (*s3Manifest, error) {
+	manifest := &s3Manifest{}
+	for relPath, localPath := range localPaths {
+		digest, size, err := sha256File(localPath)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error hashing file '%s'", localPath)
+		}
+		manifest.Files = append(manifest.Files, s3ManifestEntry{
+			Path:   relPath,
+			Size:   size,
+			SHA256: digest,
+		})
+	}
+	return manifest, nil
+}
+
+func sha256File(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, errors.WithStack(err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, errors.WithStack(err)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+func writeManifest(ctx context.Context, bucket pail.Bucket, remotePrefix string, manifest *s3Manifest) error {
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return errors.Wrap(err, "error marshalling manifest")
+	}
+	return errors.Wrap(bucket.Put(ctx, manifestKeyForPrefix(remotePrefix), bytes.NewReader(data)), "error writing manifest")
+}
+
+func fetchManifest(ctx context.Context, bucket pail.Bucket, remotePrefix string) (*s3Manifest, error) {
+	reader, err := bucket.Get(ctx, manifestKeyForPrefix(remotePrefix))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	defer reader.Close()
+
+	manifest := &s3Manifest{}
+	if err := json.NewDecoder(reader).Decode(manifest); err != nil {
+		return nil, errors.Wrap(err, "error decoding manifest")
+	}
+	return manifest, nil
+}
+
+// localFileMatchesDigest reports whether the file at localPath already has
+// the same size and SHA-256 digest as entry, so s3Pull can skip
+// re-downloading it when resuming.
+func localFileMatchesDigest(localPath string, entry s3ManifestEntry) (bool, error) {
+	info, err := os.Stat(localPath)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	if info.Size() != entry.Size {
+		return false, nil
+	}
+
+	digest, _, err := sha256File(localPath)
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	return digest == entry.SHA256, nil
+}
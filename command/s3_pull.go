@@ -0,0 +1,298 @@
+package command
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/pail"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// s3Pull retrieves the task directory another task previously pushed to
+// S3 with s3Push, so that data can be shared between tasks in the same
+// version without being re-generated.
+type s3Pull struct {
+	// ExcludeFilter is an optional regex of paths to skip when pulling.
+	ExcludeFilter string `mapstructure:"exclude"`
+	// BuildVariants restricts the pull to the given build variants; if
+	// the running task's build variant isn't in the list, the command
+	// is a noop.
+	BuildVariants []string `mapstructure:"build_variants"`
+	// MaxRetries is the number of times to retry a file whose digest
+	// doesn't match the manifest before failing the command.
+	MaxRetries uint `mapstructure:"max_retries"`
+	// TaskName is the name of the task whose artifacts are being pulled.
+	TaskName string `mapstructure:"task"`
+	// WorkingDir is the local directory the pulled files are written to.
+	WorkingDir string `mapstructure:"working_directory"`
+	// DeleteOnSync deletes the remote copy of the task directory once
+	// the pull succeeds.
+	DeleteOnSync bool `mapstructure:"delete_on_sync"`
+	// Verify controls whether the manifest s3Push wrote is fetched and
+	// used to verify the pulled files. Defaults to true.
+	Verify bool `mapstructure:"verify"`
+	// Resume controls whether files that already match the manifest on
+	// disk are skipped and partially-downloaded files are resumed with
+	// a ranged GET rather than re-fetched from scratch. Defaults to
+	// true.
+	Resume bool `mapstructure:"resume"`
+
+	// StorageBackend selects which pail.Bucket implementation backs
+	// this command. If unset, the command behaves exactly as it did
+	// before this field existed, using conf.S3Data directly.
+	StorageBackend string `mapstructure:"storage_backend"`
+
+	bucket pail.Bucket
+}
+
+func s3PullFactory() Command { return &s3Pull{} }
+
+func (c *s3Pull) Name() string { return "s3.pull" }
+
+func (c *s3Pull) ParseParams(params map[string]interface{}) error {
+	c.Verify = true
+	c.Resume = true
+
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           c,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error constructing params decoder")
+	}
+	if err := decoder.Decode(params); err != nil {
+		return errors.Wrap(err, "error decoding s3.pull params")
+	}
+
+	if c.WorkingDir == "" {
+		return errors.New("working_directory must not be empty")
+	}
+
+	return nil
+}
+
+func (c *s3Pull) shouldRunForVariant(buildVariant string) bool {
+	if len(c.BuildVariants) == 0 {
+		return true
+	}
+	for _, bv := range c.BuildVariants {
+		if bv == buildVariant {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *s3Pull) Execute(ctx context.Context, comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+	if !c.shouldRunForVariant(conf.BuildVariant.Name) {
+		logger.Task().Infof("Skipping s3.pull for build variant '%s'.", conf.BuildVariant.Name)
+		return nil
+	}
+
+	c.ExcludeFilter = conf.Expansions.ExpandString(c.ExcludeFilter)
+
+	if c.bucket == nil {
+		if c.StorageBackend == "" {
+			if conf.S3Data.Key == "" {
+				return errors.New("S3 key must not be empty")
+			}
+			if conf.S3Data.Secret == "" {
+				return errors.New("S3 secret must not be empty")
+			}
+			if conf.S3Data.Bucket == "" {
+				return errors.New("S3 bucket must not be empty")
+			}
+		}
+
+		bucket, err := newBucketForBackend(conf, c.StorageBackend)
+		if err != nil {
+			return errors.Wrap(err, "error constructing storage bucket")
+		}
+		c.bucket = bucket
+	}
+
+	remotePrefix := conf.S3Path(c.TaskName)
+
+	var manifest *s3Manifest
+	if c.Verify {
+		m, err := fetchManifest(ctx, c.bucket, remotePrefix)
+		if err != nil {
+			logger.Task().Warningf("No manifest found for '%s', falling back to unverified pull: %s", remotePrefix, err.Error())
+		} else {
+			manifest = m
+		}
+	}
+
+	iter, err := c.bucket.List(ctx, remotePrefix)
+	if err != nil {
+		return errors.Wrapf(err, "error listing '%s'", remotePrefix)
+	}
+
+	for iter.Next(ctx) {
+		item := iter.Item()
+		relPath := strings.TrimPrefix(strings.TrimPrefix(item.Name(), remotePrefix), "/")
+		if relPath == "" || relPath == s3ManifestName {
+			continue
+		}
+		if c.ExcludeFilter != "" {
+			matched, err := regexp.MatchString(c.ExcludeFilter, relPath)
+			if err == nil && matched {
+				continue
+			}
+		}
+
+		localPath := filepath.Join(c.WorkingDir, filepath.Base(relPath))
+		if err := c.pullFile(ctx, item, manifest, relPath, localPath); err != nil {
+			return errors.Wrapf(err, "error pulling '%s'", relPath)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.Wrapf(err, "error iterating '%s'", remotePrefix)
+	}
+
+	if c.DeleteOnSync {
+		return errors.Wrapf(c.bucket.RemovePrefix(ctx, remotePrefix), "error deleting '%s' after pull", remotePrefix)
+	}
+
+	return nil
+}
+
+// pullFile downloads a single remote object to localPath, optionally
+// resuming a partial download and verifying the result against the
+// manifest entry for relPath, retrying on digest mismatch up to
+// c.MaxRetries times.
+func (c *s3Pull) pullFile(ctx context.Context, item pail.BucketItem, manifest *s3Manifest, relPath, localPath string) error {
+	var entry *s3ManifestEntry
+	if manifest != nil {
+		for i := range manifest.Files {
+			if manifest.Files[i].Path == relPath {
+				entry = &manifest.Files[i]
+				break
+			}
+		}
+	}
+
+	if c.Resume && entry != nil {
+		if ok, err := localFileMatchesDigest(localPath, *entry); err == nil && ok {
+			return nil
+		}
+	}
+
+	attempts := c.MaxRetries + 1
+	var lastErr error
+	for attempt := uint(0); attempt < attempts; attempt++ {
+		if err := c.downloadFile(ctx, item, localPath, entry); err != nil {
+			lastErr = err
+			continue
+		}
+
+		if entry == nil {
+			return nil
+		}
+		ok, err := localFileMatchesDigest(localPath, *entry)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if ok {
+			return nil
+		}
+		lastErr = errors.Errorf("digest mismatch for '%s' after download", relPath)
+	}
+
+	return errors.Wrapf(lastErr, "failed to pull '%s' after %d attempt(s)", relPath, attempts)
+}
+
+// rangedBucket is implemented by pail.Bucket backends (e.g. S3) that can
+// fetch an object starting at a byte offset without retransmitting
+// everything before it. Backends that don't implement it (e.g.
+// pail.LocalBucket) fall back to a full fetch with the leading bytes
+// discarded.
+type rangedBucket interface {
+	GetRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error)
+}
+
+// downloadFile fetches item to localPath. If c.Resume is set and localPath
+// already holds a prefix of the remote object shorter than the manifest
+// entry's size, only the missing tail is fetched (via a true ranged GET
+// when the bucket supports one, or a skipped-prefix fetch otherwise) and
+// appended to the existing file; otherwise the file is fetched from
+// scratch.
+func (c *s3Pull) downloadFile(ctx context.Context, item pail.BucketItem, localPath string, entry *s3ManifestEntry) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0777); err != nil {
+		return errors.WithStack(err)
+	}
+
+	var resumeOffset int64
+	if c.Resume && entry != nil {
+		if info, err := os.Stat(localPath); err == nil && info.Size() > 0 && info.Size() < entry.Size {
+			resumeOffset = info.Size()
+		}
+	}
+
+	reader, err := c.openAt(ctx, item, resumeOffset)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer reader.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	if resumeOffset > 0 {
+		flags |= os.O_APPEND
+	} else {
+		flags |= os.O_TRUNC
+	}
+	out, err := os.OpenFile(localPath, flags, 0666)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, reader)
+	return errors.WithStack(err)
+}
+
+// openAt returns a reader positioned at offset bytes into item, using a
+// true ranged GET when the bucket supports it and otherwise fetching the
+// full object and discarding the leading offset bytes.
+func (c *s3Pull) openAt(ctx context.Context, item pail.BucketItem, offset int64) (io.ReadCloser, error) {
+	if offset == 0 {
+		return item.Get(ctx)
+	}
+
+	if rb, ok := c.bucket.(rangedBucket); ok {
+		return rb.GetRange(ctx, item.Name(), offset, 0)
+	}
+
+	reader, err := item.Get(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if _, err := io.CopyN(ioutil.Discard, reader, offset); err != nil {
+		reader.Close()
+		return nil, errors.Wrap(err, "error seeking to resume offset")
+	}
+	return reader, nil
+}
+
+func newS3Bucket(s3Data apimodels.S3TaskSetupData) (pail.Bucket, error) {
+	return pail.NewS3Bucket(pail.S3Options{
+		Name:   s3Data.Bucket,
+		Prefix: "",
+		Region: "us-east-1",
+		Credentials: pail.CreateAWSCredentials(
+			s3Data.Key,
+			s3Data.Secret,
+			"",
+		),
+	})
+}
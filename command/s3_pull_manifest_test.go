@@ -0,0 +1,163 @@
+package command
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/pail"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupS3PullManifestTest(t *testing.T) (context.Context, *s3Pull, *client.Mock, client.LoggerProducer, *model.TaskConfig, string, func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	conf := &model.TaskConfig{
+		Task: &task.Task{
+			Id:           "id",
+			Secret:       "secret",
+			Version:      "version",
+			BuildVariant: "build_variant",
+			DisplayName:  "display_name",
+		},
+		BuildVariant: &model.BuildVariant{
+			Name: "build_variant",
+		},
+		ProjectRef: &model.ProjectRef{
+			Identifier: "project_identifier",
+		},
+		S3Data: apimodels.S3TaskSetupData{
+			Key:    "s3_key",
+			Secret: "s3_secret",
+			Bucket: "s3_bucket",
+		},
+	}
+	comm := client.NewMock("localhost")
+	logger, err := comm.GetLoggerProducer(ctx, client.TaskData{ID: conf.Task.Id, Secret: conf.Task.Secret}, nil)
+	require.NoError(t, err)
+
+	bucketDir, err := ioutil.TempDir("", "s3-pull-manifest-bucket")
+	require.NoError(t, err)
+
+	c := &s3Pull{TaskName: "test", Verify: true, Resume: true}
+	c.bucket, err = pail.NewLocalBucket(pail.LocalOptions{Path: bucketDir})
+	require.NoError(t, err)
+
+	c.WorkingDir, err = ioutil.TempDir("", "s3-pull-manifest-output")
+	require.NoError(t, err)
+
+	cleanup := func() {
+		cancel()
+		assert.NoError(t, os.RemoveAll(bucketDir))
+		assert.NoError(t, os.RemoveAll(c.WorkingDir))
+	}
+
+	return ctx, c, comm, logger, conf, bucketDir, cleanup
+}
+
+func TestS3PullVerifiesManifest(t *testing.T) {
+	ctx, c, comm, logger, conf, bucketDir, cleanup := setupS3PullManifestTest(t)
+	defer cleanup()
+
+	taskDir := filepath.Join(bucketDir, conf.S3Path("test"))
+	require.NoError(t, os.MkdirAll(taskDir, 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(taskDir, "file1"), []byte("hello world"), 0666))
+
+	manifest, err := buildManifest(map[string]string{"file1": filepath.Join(taskDir, "file1")})
+	require.NoError(t, err)
+	require.NoError(t, writeManifest(ctx, c.bucket, conf.S3Path("test"), manifest))
+
+	require.NoError(t, c.Execute(ctx, comm, logger, conf))
+
+	data, err := ioutil.ReadFile(filepath.Join(c.WorkingDir, "file1"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(data))
+}
+
+func TestS3PullFailsOnManifestMismatch(t *testing.T) {
+	ctx, c, comm, logger, conf, bucketDir, cleanup := setupS3PullManifestTest(t)
+	defer cleanup()
+	c.MaxRetries = 1
+
+	taskDir := filepath.Join(bucketDir, conf.S3Path("test"))
+	require.NoError(t, os.MkdirAll(taskDir, 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(taskDir, "file1"), []byte("hello world"), 0666))
+
+	manifest := &s3Manifest{Files: []s3ManifestEntry{{Path: "file1", Size: 11, SHA256: "deadbeef"}}}
+	require.NoError(t, writeManifest(ctx, c.bucket, conf.S3Path("test"), manifest))
+
+	assert.Error(t, c.Execute(ctx, comm, logger, conf))
+}
+
+func TestS3PullSkipsCompleteMatchingFile(t *testing.T) {
+	ctx, c, comm, logger, conf, bucketDir, cleanup := setupS3PullManifestTest(t)
+	defer cleanup()
+
+	taskDir := filepath.Join(bucketDir, conf.S3Path("test"))
+	require.NoError(t, os.MkdirAll(taskDir, 0777))
+	content := []byte("already on disk")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(taskDir, "file1"), content, 0666))
+
+	manifest, err := buildManifest(map[string]string{"file1": filepath.Join(taskDir, "file1")})
+	require.NoError(t, err)
+	require.NoError(t, writeManifest(ctx, c.bucket, conf.S3Path("test"), manifest))
+
+	// Pre-populate the working directory with the matching content so the
+	// pull can be satisfied without a fresh download.
+	require.NoError(t, ioutil.WriteFile(filepath.Join(c.WorkingDir, "file1"), content, 0666))
+
+	require.NoError(t, c.Execute(ctx, comm, logger, conf))
+
+	data, err := ioutil.ReadFile(filepath.Join(c.WorkingDir, "file1"))
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestS3PullResumesPartialFile(t *testing.T) {
+	ctx, c, comm, logger, conf, bucketDir, cleanup := setupS3PullManifestTest(t)
+	defer cleanup()
+
+	taskDir := filepath.Join(bucketDir, conf.S3Path("test"))
+	require.NoError(t, os.MkdirAll(taskDir, 0777))
+	content := []byte("the quick brown fox jumps over the lazy dog")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(taskDir, "file1"), content, 0666))
+
+	manifest, err := buildManifest(map[string]string{"file1": filepath.Join(taskDir, "file1")})
+	require.NoError(t, err)
+	require.NoError(t, writeManifest(ctx, c.bucket, conf.S3Path("test"), manifest))
+
+	// Pre-populate the working directory with only the first half of the
+	// file, simulating a download that was interrupted partway through.
+	truncated := content[:len(content)/2]
+	localPath := filepath.Join(c.WorkingDir, "file1")
+	require.NoError(t, ioutil.WriteFile(localPath, truncated, 0666))
+
+	require.NoError(t, c.Execute(ctx, comm, logger, conf))
+
+	data, err := ioutil.ReadFile(localPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+func TestS3PullFallsBackWithoutVerify(t *testing.T) {
+	ctx, c, comm, logger, conf, bucketDir, cleanup := setupS3PullManifestTest(t)
+	defer cleanup()
+	c.Verify = false
+
+	taskDir := filepath.Join(bucketDir, conf.S3Path("test"))
+	require.NoError(t, os.MkdirAll(taskDir, 0777))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(taskDir, "file1"), []byte("no manifest here"), 0666))
+
+	require.NoError(t, c.Execute(ctx, comm, logger, conf))
+
+	data, err := ioutil.ReadFile(filepath.Join(c.WorkingDir, "file1"))
+	require.NoError(t, err)
+	assert.Equal(t, "no manifest here", string(data))
+}
@@ -0,0 +1,114 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/pail"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// s3Push uploads the task directory to S3 under a task-specific prefix so
+// that a later task in the same version can retrieve it with s3Pull.
+type s3Push struct {
+	ExcludeFilter string `mapstructure:"exclude"`
+
+	// StorageBackend selects which pail.Bucket implementation backs
+	// this command. If unset, the command behaves exactly as it did
+	// before this field existed, using conf.S3Data directly.
+	StorageBackend string `mapstructure:"storage_backend"`
+
+	bucket pail.Bucket
+}
+
+func s3PushFactory() Command { return &s3Push{} }
+
+func (c *s3Push) Name() string { return "s3.push" }
+
+func (c *s3Push) ParseParams(params map[string]interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           c,
+		WeaklyTypedInput: true,
+	})
+	if err != nil {
+		return errors.Wrap(err, "error constructing params decoder")
+	}
+	return errors.Wrap(decoder.Decode(params), "error decoding s3.push params")
+}
+
+func (c *s3Push) Execute(ctx context.Context, comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+	c.ExcludeFilter = conf.Expansions.ExpandString(c.ExcludeFilter)
+
+	if c.bucket == nil {
+		if c.StorageBackend == "" {
+			if conf.S3Data.Key == "" || conf.S3Data.Secret == "" || conf.S3Data.Bucket == "" {
+				return errors.New("S3 key, secret, and bucket must all be set")
+			}
+		}
+		bucket, err := newBucketForBackend(conf, c.StorageBackend)
+		if err != nil {
+			return errors.Wrap(err, "error constructing storage bucket")
+		}
+		c.bucket = bucket
+	}
+
+	remotePrefix := conf.S3Path(conf.Task.DisplayName)
+
+	localPaths, err := c.collectFiles(conf.WorkDir)
+	if err != nil {
+		return errors.Wrap(err, "error collecting files to push")
+	}
+
+	for relPath, localPath := range localPaths {
+		f, err := os.Open(localPath)
+		if err != nil {
+			return errors.Wrapf(err, "error opening '%s'", localPath)
+		}
+		err = c.bucket.Put(ctx, remotePrefix+"/"+relPath, f)
+		closeErr := f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "error uploading '%s'", relPath)
+		}
+		if closeErr != nil {
+			return errors.Wrapf(closeErr, "error closing '%s'", localPath)
+		}
+	}
+
+	manifest, err := buildManifest(localPaths)
+	if err != nil {
+		return errors.Wrap(err, "error building manifest")
+	}
+
+	return errors.Wrap(writeManifest(ctx, c.bucket, remotePrefix, manifest), "error writing manifest")
+}
+
+// collectFiles walks workDir and returns a map of remote-relative path to
+// local absolute path for every file that doesn't match ExcludeFilter.
+func (c *s3Push) collectFiles(workDir string) (map[string]string, error) {
+	files := map[string]string{}
+	err := filepath.Walk(workDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(workDir, path)
+		if err != nil {
+			return err
+		}
+		if c.ExcludeFilter != "" {
+			if matched, err := regexp.MatchString(c.ExcludeFilter, relPath); err == nil && matched {
+				return nil
+			}
+		}
+		files[relPath] = path
+		return nil
+	})
+	return files, errors.WithStack(err)
+}
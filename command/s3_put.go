@@ -0,0 +1,68 @@
+package command
+
+import (
+	"context"
+	"os"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/client"
+	"github.com/evergreen-ci/pail"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// s3put is the legacy single-object upload command, kept alongside s3Push
+// for projects that publish one artifact at a time rather than a whole
+// task directory.
+type s3put struct {
+	LocalFile  string `mapstructure:"local_file"`
+	RemoteFile string `mapstructure:"remote_file"`
+
+	// StorageBackend selects which pail.Bucket implementation backs
+	// this command; unset means "s3" using conf.S3Data, as before.
+	StorageBackend string `mapstructure:"storage_backend"`
+
+	bucket pail.Bucket
+}
+
+func s3PutFactory() Command { return &s3put{} }
+
+func (c *s3put) Name() string { return "s3.put" }
+
+func (c *s3put) ParseParams(params map[string]interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{Result: c, WeaklyTypedInput: true})
+	if err != nil {
+		return errors.Wrap(err, "error constructing params decoder")
+	}
+	if err := decoder.Decode(params); err != nil {
+		return errors.Wrap(err, "error decoding s3.put params")
+	}
+	if c.LocalFile == "" {
+		return errors.New("local_file must not be empty")
+	}
+	if c.RemoteFile == "" {
+		return errors.New("remote_file must not be empty")
+	}
+	return nil
+}
+
+func (c *s3put) Execute(ctx context.Context, comm client.Communicator, logger client.LoggerProducer, conf *model.TaskConfig) error {
+	c.LocalFile = conf.Expansions.ExpandString(c.LocalFile)
+	c.RemoteFile = conf.Expansions.ExpandString(c.RemoteFile)
+
+	if c.bucket == nil {
+		bucket, err := newBucketForBackend(conf, c.StorageBackend)
+		if err != nil {
+			return errors.Wrap(err, "error constructing storage bucket")
+		}
+		c.bucket = bucket
+	}
+
+	f, err := os.Open(c.LocalFile)
+	if err != nil {
+		return errors.Wrapf(err, "error opening '%s'", c.LocalFile)
+	}
+	defer f.Close()
+
+	return errors.Wrapf(c.bucket.Put(ctx, c.RemoteFile, f), "error uploading '%s'", c.LocalFile)
+}
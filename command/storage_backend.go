@@ -0,0 +1,56 @@
+package command
+
+import (
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/pail"
+	"github.com/pkg/errors"
+)
+
+const (
+	storageBackendS3    = "s3"
+	storageBackendGCS   = "gcs"
+	storageBackendAzure = "azure"
+	storageBackendLocal = "local"
+)
+
+// newBucketForBackend constructs a pail.Bucket for the requested storage
+// backend using the credentials in conf.StorageData. An empty backend
+// means the command didn't set storage_backend, so it behaves exactly as
+// it did before this field existed, using the legacy conf.S3Data.
+func newBucketForBackend(conf *model.TaskConfig, backend string) (pail.Bucket, error) {
+	switch backend {
+	case "":
+		return newS3Bucket(conf.S3Data)
+	case storageBackendS3:
+		return pail.NewS3Bucket(pail.S3Options{
+			Name:   conf.StorageData.Bucket,
+			Prefix: conf.StorageData.Prefix,
+			Region: conf.StorageData.Region,
+			Credentials: pail.CreateAWSCredentials(
+				conf.StorageData.Key,
+				conf.StorageData.Secret,
+				"",
+			),
+		})
+	case storageBackendGCS:
+		return pail.NewGCSBucket(pail.GCSOptions{
+			Name:   conf.StorageData.Bucket,
+			Prefix: conf.StorageData.Prefix,
+		})
+	case storageBackendAzure:
+		return pail.NewAzureBucket(pail.AzureOptions{
+			Name:   conf.StorageData.Bucket,
+			Prefix: conf.StorageData.Prefix,
+			Credentials: pail.CreateAzureCredentials(
+				conf.StorageData.Key,
+				conf.StorageData.Secret,
+			),
+		})
+	case storageBackendLocal:
+		return pail.NewLocalBucket(pail.LocalOptions{
+			Path: conf.StorageData.LocalPath,
+		})
+	default:
+		return nil, errors.Errorf("unrecognized storage_backend '%s'", backend)
+	}
+}
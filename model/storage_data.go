@@ -0,0 +1,27 @@
+package model
+
+// StorageData holds the credentials and location needed to construct a
+// pail.Bucket for a task's artifact storage backend, independent of which
+// provider (S3, GCS, Azure, or a local directory) is actually backing it.
+// It supersedes the S3-specific apimodels.S3TaskSetupData on TaskConfig;
+// TaskConfig.S3Data is kept for back-compat with tasks that don't set
+// storage_backend.
+type StorageData struct {
+	// Backend is one of "s3", "gcs", "azure", or "local". The commands pick
+	// their backend from their own storage_backend param, not from this
+	// field, and fall back to S3Data when that param is unset.
+	Backend string `bson:"backend" json:"backend"`
+
+	Bucket string `bson:"bucket" json:"bucket"`
+	Prefix string `bson:"prefix,omitempty" json:"prefix,omitempty"`
+	Region string `bson:"region,omitempty" json:"region,omitempty"`
+
+	// Key and Secret are provider-specific credentials (an S3 access key
+	// pair, or an Azure account name/key). The "gcs" backend does not
+	// read them.
+	Key    string `bson:"key,omitempty" json:"key,omitempty"`
+	Secret string `bson:"secret,omitempty" json:"secret,omitempty"`
+
+	// LocalPath is only used when Backend is "local", e.g. in tests.
+	LocalPath string `bson:"local_path,omitempty" json:"local_path,omitempty"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"fmt"
+
+	"github.com/evergreen-ci/evergreen/apimodels"
+	"github.com/evergreen-ci/evergreen/model/task"
+	"github.com/evergreen-ci/evergreen/util"
+)
+
+// BuildVariant holds the subset of a project's build variant definition
+// that task commands need at runtime.
+type BuildVariant struct {
+	Name string `yaml:"name" bson:"name"`
+}
+
+// ProjectRef identifies the project a running task belongs to.
+type ProjectRef struct {
+	Identifier string `bson:"identifier" json:"identifier"`
+}
+
+// TaskConfig is the runtime configuration handed to task commands,
+// combining the task, project, and expansion state needed to execute.
+type TaskConfig struct {
+	Task         *task.Task
+	BuildVariant *BuildVariant
+	ProjectRef   *ProjectRef
+	Expansions   *util.Expansions
+	WorkDir      string
+
+	// S3Data holds the legacy, S3-only credentials used when a command
+	// doesn't set storage_backend.
+	S3Data apimodels.S3TaskSetupData
+
+	// StorageData supersedes S3Data, allowing a task's artifact
+	// storage to be backed by a provider other than S3.
+	StorageData StorageData
+}
+
+// S3Path returns the remote prefix under which a task's artifacts for
+// taskName are stored.
+func (c *TaskConfig) S3Path(taskName string) string {
+	return fmt.Sprintf("%s/%s/%s", c.ProjectRef.Identifier, c.Task.Version, taskName)
+}
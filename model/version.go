@@ -67,15 +67,215 @@ func (v *Version) LastSuccessful() (*Version, error) {
 	return lastGreen, nil
 }
 
+// ErrBisectionRangeUndetermined is returned by BisectFirstFailure when the
+// part of the range still left to search holds only versions in an
+// unresolved state (created/started), so the earliest failing version
+// cannot yet be determined, even if failing versions were already found
+// later in the range.
+var ErrBisectionRangeUndetermined = errors.New("bisection range is not yet fully evaluated")
+
+// BisectFirstFailure walks between v (a currently-failing version) and the
+// most recent version returned by LastSuccessful, binary-searching over
+// RevisionOrderNumber to find the earliest version in that range that is
+// failed. Each iteration fetches at most one definitive version on each side
+// of the current midpoint and keeps the nearer one, rather than scanning the
+// full history. Versions with
+// Ignored=true or a non-system requester are skipped entirely (they are
+// excluded from every query and never considered as search midpoints).
+// Versions whose status is not yet definitive (created/started) cause the
+// search to expand outward *within the current search window* for a
+// definitive neighbor; if no definitive version exists anywhere left in the
+// window, ErrBisectionRangeUndetermined is returned. Every iteration
+// strictly shrinks the window, so the search always terminates.
+func (v *Version) BisectFirstFailure(projectID string) (*Version, error) {
+	if v.Status != evergreen.VersionFailed {
+		return nil, errors.Errorf("version '%s' is not currently failing", v.Id)
+	}
+
+	lastGreen, err := v.LastSuccessful()
+	if err != nil {
+		return nil, errors.Wrap(err, "error finding last successful version")
+	}
+	if lastGreen == nil {
+		return nil, errors.Errorf("no successful version found before '%s' for project '%s'", v.Id, projectID)
+	}
+
+	lo, hi := lastGreen.RevisionOrderNumber+1, v.RevisionOrderNumber
+	firstFailure := v
+
+	for lo <= hi {
+		mid := lo + (hi-lo)/2
+
+		nearest, err := findNearestDefinitive(projectID, lo, hi, mid)
+		if err != nil {
+			return nil, errors.Wrap(err, "error fetching candidate version")
+		}
+		if nearest == nil {
+			return nil, ErrBisectionRangeUndetermined
+		}
+
+		if nearest.Status == evergreen.VersionFailed {
+			firstFailure = nearest
+			hi = nearest.RevisionOrderNumber - 1
+		} else {
+			lo = nearest.RevisionOrderNumber + 1
+		}
+	}
+
+	return firstFailure, nil
+}
+
+// findNearestDefinitive fetches the candidate version within [lo, hi] whose
+// RevisionOrderNumber is closest to mid and whose status is a definitive
+// failed/succeeded, by querying outward from mid in each direction rather
+// than loading the whole window into memory. It returns nil if no
+// definitive candidate exists anywhere in [lo, hi].
+func findNearestDefinitive(projectID string, lo, hi, mid int) (*Version, error) {
+	up, err := VersionFindOne(db.Query(bson.M{
+		VersionIdentifierKey:          projectID,
+		VersionIgnoredKey:             bson.M{"$ne": true},
+		VersionRequesterKey:           bson.M{"$in": evergreen.SystemVersionRequesterTypes},
+		VersionStatusKey:              bson.M{"$in": []string{evergreen.VersionFailed, evergreen.VersionSucceeded}},
+		VersionRevisionOrderNumberKey: bson.M{"$gte": mid, "$lte": hi},
+	}).WithoutFields(VersionConfigKey).Sort([]string{VersionRevisionOrderNumberKey}))
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching next candidate at or after midpoint")
+	}
+
+	down, err := VersionFindOne(db.Query(bson.M{
+		VersionIdentifierKey:          projectID,
+		VersionIgnoredKey:             bson.M{"$ne": true},
+		VersionRequesterKey:           bson.M{"$in": evergreen.SystemVersionRequesterTypes},
+		VersionStatusKey:              bson.M{"$in": []string{evergreen.VersionFailed, evergreen.VersionSucceeded}},
+		VersionRevisionOrderNumberKey: bson.M{"$gte": lo, "$lt": mid},
+	}).WithoutFields(VersionConfigKey).Sort([]string{"-" + VersionRevisionOrderNumberKey}))
+	if err != nil {
+		return nil, errors.Wrap(err, "error fetching next candidate before midpoint")
+	}
+
+	switch {
+	case up == nil && down == nil:
+		return nil, nil
+	case up == nil:
+		return down, nil
+	case down == nil:
+		return up, nil
+	case up.RevisionOrderNumber-mid <= mid-down.RevisionOrderNumber:
+		return up, nil
+	default:
+		return down, nil
+	}
+}
+
+// UpdateBuildVariants persists self.BuildVariants and, for each variant
+// whose Activated value actually differs from what's currently persisted,
+// records a variant_activated event in the same transaction as the update.
+// Variants whose Activated value is unchanged don't generate an event.
 func (self *Version) UpdateBuildVariants() error {
-	return VersionUpdateOne(
-		bson.M{VersionIdKey: self.Id},
-		bson.M{
-			"$set": bson.M{
-				VersionBuildVariantsKey: self.BuildVariants,
+	previous, err := VersionFindOne(VersionById(self.Id).WithoutFields(VersionConfigKey))
+	if err != nil {
+		return errors.Wrap(err, "error finding current version state")
+	}
+	prevActivated := map[string]bool{}
+	if previous != nil {
+		for _, bv := range previous.BuildVariants {
+			prevActivated[bv.BuildVariant] = bv.Activated
+		}
+	}
+
+	var events []VersionEvent
+	for _, bv := range self.BuildVariants {
+		if wasActivated, ok := prevActivated[bv.BuildVariant]; ok && wasActivated == bv.Activated {
+			continue
+		}
+		events = append(events, VersionEvent{
+			Kind: VersionEventVariantActivated,
+			Payload: bson.M{
+				"build_variant": bv.BuildVariant,
+				"activated":     bv.Activated,
+			},
+		})
+	}
+
+	return errors.Wrap(self.recordMutation(func() error {
+		return VersionUpdateOne(
+			bson.M{VersionIdKey: self.Id},
+			bson.M{
+				"$set": bson.M{
+					VersionBuildVariantsKey: self.BuildVariants,
+				},
+			},
+		)
+	}, events...), "error updating build variants")
+}
+
+// UpdateStatus sets the version's Status both in memory and in the
+// database, recording a status_change event in the same transaction as the
+// update so the transition is auditable and replayable.
+func (self *Version) UpdateStatus(status string) error {
+	err := self.recordMutation(func() error {
+		return VersionUpdateOne(
+			bson.M{VersionIdKey: self.Id},
+			bson.M{
+				"$set": bson.M{VersionStatusKey: status},
+			},
+		)
+	}, VersionEvent{
+		Kind:    VersionEventStatusChange,
+		Payload: bson.M{"status": status},
+	})
+	if err != nil {
+		return errors.Wrap(err, "error updating version status")
+	}
+
+	self.Status = status
+	return nil
+}
+
+// AddError appends msg to the version's Errors both in memory and in the
+// database, recording an error_added event in the same transaction as the
+// update.
+func (self *Version) AddError(msg string) error {
+	err := self.recordMutation(func() error {
+		return VersionUpdateOne(
+			bson.M{VersionIdKey: self.Id},
+			bson.M{
+				"$push": bson.M{VersionErrorsKey: msg},
+			},
+		)
+	}, VersionEvent{
+		Kind:    VersionEventErrorAdded,
+		Payload: bson.M{"message": msg},
+	})
+	if err != nil {
+		return errors.Wrap(err, "error appending version error")
+	}
+
+	self.Errors = append(self.Errors, msg)
+	return nil
+}
+
+// AddWarning appends msg to the version's Warnings both in memory and in
+// the database, recording a warning_added event in the same transaction as
+// the update.
+func (self *Version) AddWarning(msg string) error {
+	err := self.recordMutation(func() error {
+		return VersionUpdateOne(
+			bson.M{VersionIdKey: self.Id},
+			bson.M{
+				"$push": bson.M{VersionWarningsKey: msg},
 			},
-		},
-	)
+		)
+	}, VersionEvent{
+		Kind:    VersionEventWarningAdded,
+		Payload: bson.M{"message": msg},
+	})
+	if err != nil {
+		return errors.Wrap(err, "error appending version warning")
+	}
+
+	self.Warnings = append(self.Warnings, msg)
+	return nil
 }
 
 func (self *Version) Insert() error {
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBisectFirstFailure(t *testing.T) {
+	require.NoError(t, db.ClearCollections(VersionCollection))
+
+	versions := []Version{
+		{Id: "v1", Identifier: "proj", RevisionOrderNumber: 1, Status: evergreen.VersionSucceeded, Requester: evergreen.RepotrackerVersionRequester},
+		{Id: "v2", Identifier: "proj", RevisionOrderNumber: 2, Status: evergreen.VersionSucceeded, Requester: evergreen.RepotrackerVersionRequester},
+		{Id: "v3", Identifier: "proj", RevisionOrderNumber: 3, Status: evergreen.VersionFailed, Requester: evergreen.RepotrackerVersionRequester},
+		{Id: "v4", Identifier: "proj", RevisionOrderNumber: 4, Status: evergreen.VersionFailed, Requester: evergreen.RepotrackerVersionRequester},
+	}
+	for _, v := range versions {
+		require.NoError(t, v.Insert())
+	}
+
+	failing := versions[3]
+	firstFailure, err := failing.BisectFirstFailure("proj")
+	require.NoError(t, err)
+	require.NotNil(t, firstFailure)
+	assert.Equal(t, "v3", firstFailure.Id)
+}
+
+func TestBisectFirstFailureUndetermined(t *testing.T) {
+	require.NoError(t, db.ClearCollections(VersionCollection))
+
+	versions := []Version{
+		{Id: "v1", Identifier: "proj", RevisionOrderNumber: 1, Status: evergreen.VersionSucceeded, Requester: evergreen.RepotrackerVersionRequester},
+		{Id: "v2", Identifier: "proj", RevisionOrderNumber: 2, Status: evergreen.VersionStarted, Requester: evergreen.RepotrackerVersionRequester},
+		{Id: "v3", Identifier: "proj", RevisionOrderNumber: 3, Status: evergreen.VersionFailed, Requester: evergreen.RepotrackerVersionRequester},
+	}
+	for _, v := range versions {
+		require.NoError(t, v.Insert())
+	}
+
+	failing := versions[2]
+	_, err := failing.BisectFirstFailure("proj")
+	assert.Equal(t, ErrBisectionRangeUndetermined, errors.Cause(err))
+}
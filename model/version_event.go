@@ -0,0 +1,146 @@
+package model
+
+import (
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/mongodb/anser/bsonutil"
+	"github.com/pkg/errors"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// VersionEventsCollection is the name of the database collection that
+// stores VersionEvent documents.
+const VersionEventsCollection = "version_events"
+
+// VersionEventKind enumerates the kinds of mutation VersionEvent can
+// record against a Version.
+type VersionEventKind string
+
+const (
+	VersionEventStatusChange     VersionEventKind = "status_change"
+	VersionEventVariantActivated VersionEventKind = "variant_activated"
+	VersionEventErrorAdded       VersionEventKind = "error_added"
+	VersionEventWarningAdded     VersionEventKind = "warning_added"
+	VersionEventRestart          VersionEventKind = "restart"
+	VersionEventTrigger          VersionEventKind = "trigger"
+)
+
+// VersionEvent is an immutable record of a single mutation made to a
+// Version, used to reconstruct its state as of any point in time and to
+// give the UI an audit trail instead of just the current status.
+type VersionEvent struct {
+	VersionID string           `bson:"version_id"`
+	Timestamp time.Time        `bson:"ts"`
+	Actor     string           `bson:"actor"`
+	Kind      VersionEventKind `bson:"kind"`
+	Payload   bson.M           `bson:"payload,omitempty"`
+}
+
+var (
+	versionEventVersionIDKey = bsonutil.MustHaveTag(VersionEvent{}, "VersionID")
+	versionEventTimestampKey = bsonutil.MustHaveTag(VersionEvent{}, "Timestamp")
+)
+
+// RecordEvent persists evt and is called alongside every mutation to a
+// Version's Status, BuildVariants[].Activated, Errors, or Warnings so that
+// the change is auditable and replayable. It does not itself apply the
+// mutation; callers update the Version document and call RecordEvent for
+// the same change.
+func (v *Version) RecordEvent(evt VersionEvent) error {
+	if evt.VersionID == "" {
+		evt.VersionID = v.Id
+	}
+	if evt.Timestamp.IsZero() {
+		evt.Timestamp = time.Now()
+	}
+	return errors.Wrap(db.Insert(VersionEventsCollection, evt), "error recording version event")
+}
+
+// recordMutation runs updateFn (expected to be a VersionUpdateOne call
+// against v's document) and persists events, all inside a single database
+// transaction, so a crash or error between the version update and its
+// version_events audit entries can't leave the two out of sync. If events
+// is empty there's nothing to keep in sync, so updateFn runs on its own
+// without the overhead of a transaction.
+func (v *Version) recordMutation(updateFn func() error, events ...VersionEvent) error {
+	if len(events) == 0 {
+		return updateFn()
+	}
+
+	return errors.WithStack(db.WithTransaction(func() error {
+		if err := updateFn(); err != nil {
+			return err
+		}
+		for _, evt := range events {
+			if err := v.RecordEvent(evt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// VersionEventsFor returns every VersionEvent recorded against versionID,
+// ordered oldest first.
+func VersionEventsFor(versionID string) ([]VersionEvent, error) {
+	events := []VersionEvent{}
+	err := db.FindAllQ(VersionEventsCollection, db.Query(bson.M{versionEventVersionIDKey: versionID}).Sort([]string{versionEventTimestampKey}), &events)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error finding events for version '%s'", versionID)
+	}
+	return events, nil
+}
+
+// ReplayTo reconstructs v's state as of time t by folding every recorded
+// event up to and including t over a copy of v with its Errors and Warnings
+// cleared. The copy's Status and BuildVariants start from v's current
+// values, not from the document as first inserted, so a field with no
+// event at or before t keeps v's current value. It does not mutate v or
+// persist anything; it returns a new Version reflecting the replayed state.
+func (v *Version) ReplayTo(t time.Time) (*Version, error) {
+	events, err := VersionEventsFor(v.Id)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	replayed := *v
+	replayed.Errors = nil
+	replayed.Warnings = nil
+	replayed.BuildVariants = append([]VersionBuildStatus{}, v.BuildVariants...)
+
+	for _, evt := range events {
+		if evt.Timestamp.After(t) {
+			break
+		}
+		applyVersionEvent(&replayed, evt)
+	}
+
+	return &replayed, nil
+}
+
+// applyVersionEvent folds a single event into v's in-memory state.
+func applyVersionEvent(v *Version, evt VersionEvent) {
+	switch evt.Kind {
+	case VersionEventStatusChange:
+		if status, ok := evt.Payload["status"].(string); ok {
+			v.Status = status
+		}
+	case VersionEventVariantActivated:
+		name, _ := evt.Payload["build_variant"].(string)
+		activated, _ := evt.Payload["activated"].(bool)
+		for i := range v.BuildVariants {
+			if v.BuildVariants[i].BuildVariant == name {
+				v.BuildVariants[i].Activated = activated
+			}
+		}
+	case VersionEventErrorAdded:
+		if msg, ok := evt.Payload["message"].(string); ok {
+			v.Errors = append(v.Errors, msg)
+		}
+	case VersionEventWarningAdded:
+		if msg, ok := evt.Payload["message"].(string); ok {
+			v.Warnings = append(v.Warnings, msg)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/evergreen-ci/evergreen/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestVersionEventsForAndReplayTo(t *testing.T) {
+	require.NoError(t, db.ClearCollections(VersionCollection, VersionEventsCollection))
+
+	v := Version{Id: "v1", Identifier: "proj", Status: "created"}
+	require.NoError(t, v.Insert())
+
+	t0 := time.Now()
+	require.NoError(t, v.RecordEvent(VersionEvent{Timestamp: t0, Kind: VersionEventStatusChange, Payload: bson.M{"status": "started"}}))
+
+	t1 := t0.Add(time.Minute)
+	require.NoError(t, v.RecordEvent(VersionEvent{Timestamp: t1, Kind: VersionEventStatusChange, Payload: bson.M{"status": "failed"}}))
+	require.NoError(t, v.RecordEvent(VersionEvent{Timestamp: t1, Kind: VersionEventErrorAdded, Payload: bson.M{"message": "compile error"}}))
+
+	events, err := VersionEventsFor("v1")
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	replayed, err := v.ReplayTo(t0)
+	require.NoError(t, err)
+	assert.Equal(t, "started", replayed.Status)
+	assert.Empty(t, replayed.Errors)
+
+	replayed, err = v.ReplayTo(t1)
+	require.NoError(t, err)
+	assert.Equal(t, "failed", replayed.Status)
+	assert.Equal(t, []string{"compile error"}, replayed.Errors)
+}
+
+func TestUpdateBuildVariantsOnlyEmitsEventsForChangedVariants(t *testing.T) {
+	require.NoError(t, db.ClearCollections(VersionCollection, VersionEventsCollection))
+
+	v := Version{
+		Id:         "v1",
+		Identifier: "proj",
+		BuildVariants: []VersionBuildStatus{
+			{BuildVariant: "bv1", Activated: false},
+			{BuildVariant: "bv2", Activated: false},
+		},
+	}
+	require.NoError(t, v.Insert())
+
+	v.BuildVariants[0].Activated = true
+	require.NoError(t, v.UpdateBuildVariants())
+
+	events, err := VersionEventsFor("v1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, VersionEventVariantActivated, events[0].Kind)
+	assert.Equal(t, "bv1", events[0].Payload["build_variant"])
+
+	// Persisting the exact same state again shouldn't emit another event.
+	require.NoError(t, v.UpdateBuildVariants())
+	events, err = VersionEventsFor("v1")
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,13 @@
+package route
+
+import (
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/gimlet"
+)
+
+// AttachHandler attaches the handlers in this package to app, so they are
+// reachable over HTTP.
+func AttachHandler(app *gimlet.APIApp, sc data.Connector) {
+	app.AddRoute("/versions/{version_id}/bisect/first_failure").Version(2).Get().RouteHandler(makeVersionBisectFirstFailureHandler(sc))
+	app.AddRoute("/versions/{version_id}/events").Version(2).Get().RouteHandler(makeVersionEventsHandler(sc))
+}
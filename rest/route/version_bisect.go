@@ -0,0 +1,61 @@
+package route
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	restModel "github.com/evergreen-ci/evergreen/rest/model"
+	"github.com/evergreen-ci/gimlet"
+	"github.com/pkg/errors"
+)
+
+// GET /versions/{version_id}/bisect/first_failure
+
+type versionBisectFirstFailureHandler struct {
+	versionId string
+
+	sc data.Connector
+}
+
+func makeVersionBisectFirstFailureHandler(sc data.Connector) gimlet.RouteHandler {
+	return &versionBisectFirstFailureHandler{sc: sc}
+}
+
+func (h *versionBisectFirstFailureHandler) Factory() gimlet.RouteHandler {
+	return &versionBisectFirstFailureHandler{sc: h.sc}
+}
+
+func (h *versionBisectFirstFailureHandler) Parse(ctx context.Context, r *http.Request) error {
+	h.versionId = gimlet.GetVars(r)["version_id"]
+	if h.versionId == "" {
+		return errors.New("version_id cannot be empty")
+	}
+	return nil
+}
+
+func (h *versionBisectFirstFailureHandler) Run(ctx context.Context) gimlet.Responder {
+	v, err := h.sc.FindVersionById(h.versionId)
+	if err != nil {
+		return gimlet.MakeJSONErrorResponder(errors.Wrapf(err, "error finding version '%s'", h.versionId))
+	}
+
+	firstFailure, err := v.BisectFirstFailure(v.Identifier)
+	if err != nil {
+		if errors.Cause(err) == model.ErrBisectionRangeUndetermined {
+			return gimlet.MakeJSONErrorResponder(gimlet.ErrorResponse{
+				StatusCode: http.StatusConflict,
+				Message:    err.Error(),
+			})
+		}
+		return gimlet.MakeJSONErrorResponder(errors.Wrap(err, "error bisecting version history"))
+	}
+
+	apiVersion := &restModel.APIVersion{}
+	if err = apiVersion.BuildFromService(firstFailure); err != nil {
+		return gimlet.MakeJSONInternalErrorResponder(errors.Wrap(err, "error building response version"))
+	}
+
+	return gimlet.NewJSONResponse(apiVersion)
+}
@@ -0,0 +1,24 @@
+package route
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionBisectFirstFailureHandlerParse(t *testing.T) {
+	h := makeVersionBisectFirstFailureHandler(nil).(*versionBisectFirstFailureHandler)
+
+	r := httptest.NewRequest(http.MethodGet, "/versions/v1/bisect/first_failure", nil)
+	r = mux.SetURLVars(r, map[string]string{"version_id": "v1"})
+	assert.NoError(t, h.Parse(context.Background(), r))
+	assert.Equal(t, "v1", h.versionId)
+
+	r = httptest.NewRequest(http.MethodGet, "/versions//bisect/first_failure", nil)
+	r = mux.SetURLVars(r, map[string]string{})
+	assert.Error(t, h.Parse(context.Background(), r))
+}
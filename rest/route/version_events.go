@@ -0,0 +1,52 @@
+package route
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/evergreen-ci/evergreen/model"
+	"github.com/evergreen-ci/evergreen/rest/data"
+	"github.com/evergreen-ci/gimlet"
+	"github.com/pkg/errors"
+)
+
+// GET /versions/{version_id}/events
+
+type versionEventsHandler struct {
+	versionId string
+
+	sc data.Connector
+}
+
+func makeVersionEventsHandler(sc data.Connector) gimlet.RouteHandler {
+	return &versionEventsHandler{sc: sc}
+}
+
+func (h *versionEventsHandler) Factory() gimlet.RouteHandler {
+	return &versionEventsHandler{sc: h.sc}
+}
+
+func (h *versionEventsHandler) Parse(ctx context.Context, r *http.Request) error {
+	h.versionId = gimlet.GetVars(r)["version_id"]
+	if h.versionId == "" {
+		return errors.New("version_id cannot be empty")
+	}
+	return nil
+}
+
+func (h *versionEventsHandler) Run(ctx context.Context) gimlet.Responder {
+	events, err := model.VersionEventsFor(h.versionId)
+	if err != nil {
+		return gimlet.MakeJSONErrorResponder(errors.Wrapf(err, "error finding events for version '%s'", h.versionId))
+	}
+
+	resp := gimlet.NewResponseBuilder()
+	for _, evt := range events {
+		if err := resp.AddData(evt); err != nil {
+			return gimlet.MakeJSONInternalErrorResponder(errors.Wrap(err, "error building response"))
+		}
+	}
+	resp.SetStatus(http.StatusOK)
+
+	return resp
+}
@@ -0,0 +1,322 @@
+package units
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud"
+	"github.com/evergreen-ci/evergreen/model/host"
+	"github.com/mongodb/amboy"
+	"github.com/mongodb/amboy/job"
+	"github.com/mongodb/amboy/registry"
+	"github.com/mongodb/grip"
+	"github.com/mongodb/grip/message"
+	"github.com/pkg/errors"
+)
+
+const (
+	cloudHostReadyJobName = "cloud-host-ready"
+
+	// maxCloudHostReadyJobWorkers bounds the number of regions polled
+	// concurrently so a project with an unusually large number of
+	// distinct regions can't exhaust the job's connection pool.
+	maxCloudHostReadyJobWorkers = 16
+)
+
+func init() {
+	registry.AddJobType(cloudHostReadyJobName, func() amboy.Job {
+		return makeCloudHostReadyJob()
+	})
+}
+
+type cloudHostReadyJob struct {
+	job.Base `bson:"job_base" json:"job_base" yaml:"job_base"`
+
+	env evergreen.Environment
+}
+
+func makeCloudHostReadyJob() *cloudHostReadyJob {
+	j := &cloudHostReadyJob{
+		Base: job.Base{
+			JobType: amboy.JobType{
+				Name:    cloudHostReadyJobName,
+				Version: 0,
+			},
+		},
+	}
+	return j
+}
+
+// NewCloudHostReadyJob creates a job to poll the cloud provider for the
+// status of all hosts that are currently starting, grouped by region so
+// that one wedged region doesn't block the others.
+func NewCloudHostReadyJob(env evergreen.Environment, id string) amboy.Job {
+	j := makeCloudHostReadyJob()
+	j.env = env
+	j.SetID(fmt.Sprintf("%s.%s", cloudHostReadyJobName, id))
+	return j
+}
+
+// regionGroup holds the hosts that share a (provider, region) pair and will
+// be polled together through a single cloud.Manager.
+type regionGroup struct {
+	provider string
+	region   string
+	hosts    []host.Host
+}
+
+func (g regionGroup) key() string {
+	return fmt.Sprintf("%s:%s", g.provider, g.region)
+}
+
+func (j *cloudHostReadyJob) Run(ctx context.Context) {
+	defer j.MarkComplete()
+
+	startingHosts, err := host.Find(host.IsStarting())
+	if err != nil {
+		j.AddError(errors.Wrap(err, "error fetching starting hosts"))
+		return
+	}
+	if len(startingHosts) == 0 {
+		return
+	}
+
+	groups := groupHostsByRegion(startingHosts)
+	hostKeys := hostClientKeys(groups)
+
+	limit := len(groups)
+	if limit > maxCloudHostReadyJobWorkers {
+		limit = maxCloudHostReadyJobWorkers
+	}
+
+	work := make(chan regionGroup, len(groups))
+	for _, g := range groups {
+		work <- g
+	}
+	close(work)
+
+	errs := make(chan error, len(groups))
+	unknownErrs := make(chan string, len(groups))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range work {
+				if ctx.Err() != nil {
+					errs <- errors.Wrap(ctx.Err(), "context canceled before region finished polling")
+					continue
+				}
+				unknownErr, err := j.pollRegion(ctx, g)
+				if unknownErr != "" {
+					unknownErrs <- unknownErr
+				}
+				if err != nil {
+					errs <- errors.Wrapf(err, "error polling region '%s'", g.key())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	close(unknownErrs)
+
+	var rawErrs []string
+	for awsErr := range unknownErrs {
+		rawErrs = append(rawErrs, awsErr)
+	}
+	unknown := routeUnknownInstances(rawErrs, hostKeys)
+	if len(unknown) > 0 {
+		j.AddError(errors.Wrap(j.terminateUnknownHosts(ctx, unknown), "error terminating unknown hosts"))
+	}
+
+	for err := range errs {
+		j.AddError(err)
+	}
+}
+
+// hostClientKeys maps every host ID across groups to the cloud.ClientKey
+// for the region it was polled in, so a batched AWS error naming instances
+// from multiple regions can be routed by the instance's true region rather
+// than the region whose poll happened to surface the error.
+func hostClientKeys(groups []regionGroup) map[string]cloud.ClientKey {
+	keys := map[string]cloud.ClientKey{}
+	for _, g := range groups {
+		key := cloud.ClientKey{Provider: g.provider, Region: g.region}
+		for _, h := range g.hosts {
+			keys[h.Id] = key
+		}
+	}
+	return keys
+}
+
+// routeUnknownInstances parses every raw AWS error in awsErrs for the
+// instance IDs it names and buckets each one under the cloud.ClientKey it
+// actually belongs to per hostKeys. A single error naming instances from
+// more than one region is split correctly because each ID is looked up
+// individually, rather than the whole error being attributed to one region.
+// IDs with no entry in hostKeys are dropped, since there's no region to
+// route their termination through.
+func routeUnknownInstances(awsErrs []string, hostKeys map[string]cloud.ClientKey) map[cloud.ClientKey][]string {
+	unknown := map[cloud.ClientKey][]string{}
+	for _, awsErr := range awsErrs {
+		for _, id := range parseUnknownInstanceIDs(awsErr) {
+			key, ok := hostKeys[id]
+			if !ok {
+				continue
+			}
+			unknown[key] = append(unknown[key], id)
+		}
+	}
+	return unknown
+}
+
+// pollRegion polls a single (provider, region) group of hosts and applies
+// any resulting status transitions. If the provider reports that some
+// instances no longer exist, the raw error is returned as unknownErr so
+// the caller can parse and route it, since a single batched AWS error can
+// name instances belonging to regions other than the one polled here.
+func (j *cloudHostReadyJob) pollRegion(ctx context.Context, g regionGroup) (unknownErr string, err error) {
+	startAt := time.Now()
+
+	mgr, err := cloud.GetManager(ctx, j.env, cloud.ManagerOpts{Provider: g.provider, Region: g.region})
+	if err != nil {
+		return "", errors.Wrapf(err, "error getting cloud manager for '%s'", g.key())
+	}
+
+	statuses, err := mgr.GetInstanceStatuses(ctx, g.hosts)
+	if err != nil {
+		if strings.Contains(err.Error(), "InvalidInstanceID.NotFound") {
+			return err.Error(), nil
+		}
+		return "", errors.Wrapf(err, "error describing instances in '%s'", g.key())
+	}
+
+	transitioned := 0
+	for i, h := range g.hosts {
+		if i >= len(statuses) {
+			break
+		}
+		if statuses[i] == cloud.StatusRunning && h.Status == evergreen.HostStarting {
+			if err := h.SetStatus(evergreen.HostProvisioning, evergreen.User, "host is now running"); err != nil {
+				j.AddError(errors.Wrapf(err, "error setting host '%s' provisioning", h.Id))
+				continue
+			}
+			transitioned++
+		}
+	}
+
+	grip.Info(message.Fields{
+		"message":       "polled region for cloud host status",
+		"job":           cloudHostReadyJobName,
+		"provider":      g.provider,
+		"region":        g.region,
+		"num_polled":    len(g.hosts),
+		"num_complete":  transitioned,
+		"duration_secs": time.Since(startAt).Seconds(),
+	})
+
+	return "", nil
+}
+
+func groupHostsByRegion(hosts []host.Host) []regionGroup {
+	index := map[string]int{}
+	groups := []regionGroup{}
+
+	for _, h := range hosts {
+		provider := h.Provider
+		region := h.Distro.GetRegion()
+		key := fmt.Sprintf("%s:%s", provider, region)
+		if i, ok := index[key]; ok {
+			groups[i].hosts = append(groups[i].hosts, h)
+			continue
+		}
+		index[key] = len(groups)
+		groups = append(groups, regionGroup{provider: provider, region: region, hosts: []host.Host{h}})
+	}
+
+	return groups
+}
+
+var invalidInstanceIDRegexp = regexp.MustCompile(`The instance IDs? '([^']+)' do(?:es)? not exist`)
+
+// parseUnknownInstanceIDs extracts the instance IDs named in a raw AWS
+// InvalidInstanceID.NotFound error string.
+func parseUnknownInstanceIDs(awsErr string) []string {
+	if !strings.Contains(awsErr, "InvalidInstanceID.NotFound") {
+		return nil
+	}
+	match := invalidInstanceIDRegexp.FindStringSubmatch(awsErr)
+	if len(match) != 2 {
+		return nil
+	}
+	ids := strings.Split(match[1], ",")
+	for i := range ids {
+		ids[i] = strings.TrimSpace(ids[i])
+	}
+	return ids
+}
+
+// terminateUnknownHosts terminates host records whose instance IDs the
+// cloud provider reported as not found, routing each termination through
+// the region-specific cloud.Manager for its cloud.ClientKey. This avoids
+// misrouting a termination call to the wrong region's client when a single
+// batch of polling spans multiple regions or providers.
+func (j *cloudHostReadyJob) terminateUnknownHosts(ctx context.Context, unknown map[cloud.ClientKey][]string) error {
+	catcher := grip.NewBasicCatcher()
+
+	for key, ids := range unknown {
+		mgr, err := cloud.GetManager(ctx, j.env, cloud.ManagerOpts{Provider: key.Provider, Region: key.Region})
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "error getting cloud manager for '%s:%s'", key.Provider, key.Region))
+			continue
+		}
+
+		for _, id := range ids {
+			h, err := host.FindOne(host.ById(id))
+			if err != nil {
+				catcher.Add(errors.Wrapf(err, "error finding host '%s'", id))
+				continue
+			}
+			if h == nil {
+				continue
+			}
+			catcher.Add(errors.Wrapf(mgr.TerminateInstance(ctx, h, evergreen.User, "instance not found"), "error terminating host '%s' in '%s:%s'", id, key.Provider, key.Region))
+		}
+	}
+
+	return catcher.Resolve()
+}
+
+// terminateUnknownHostsFromAWSError is a thin fallback for legacy callers
+// that only have a raw, unattributed AWS error string and no region
+// information. Because it cannot determine which region's cloud.Manager
+// owns an instance ID, it terminates the host record directly instead of
+// going through the provider API; prefer terminateUnknownHosts wherever a
+// cloud.ClientKey is available.
+func (j *cloudHostReadyJob) terminateUnknownHostsFromAWSError(ctx context.Context, awsErr string) error {
+	ids := parseUnknownInstanceIDs(awsErr)
+	if len(ids) == 0 {
+		return nil
+	}
+
+	catcher := grip.NewBasicCatcher()
+	for _, id := range ids {
+		h, err := host.FindOne(host.ById(id))
+		if err != nil {
+			catcher.Add(errors.Wrapf(err, "error finding host '%s'", id))
+			continue
+		}
+		if h == nil {
+			continue
+		}
+		catcher.Add(errors.Wrapf(h.SetStatus(evergreen.HostTerminated, evergreen.User, "instance not found"), "error terminating host '%s'", id))
+	}
+	return catcher.Resolve()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/evergreen-ci/birch"
 
 	"github.com/evergreen-ci/evergreen"
+	"github.com/evergreen-ci/evergreen/cloud"
 	"github.com/evergreen-ci/evergreen/db"
 	"github.com/evergreen-ci/evergreen/mock"
 	"github.com/evergreen-ci/evergreen/model/distro"
@@ -78,7 +79,7 @@ func TestCloudStatusJob(t *testing.T) {
 	}
 }
 
-func TestTerminateUnknownHosts(t *testing.T) {
+func TestTerminateUnknownHostsFromAWSError(t *testing.T) {
 	require.NoError(t, db.ClearCollections(host.Collection))
 	h1 := host.Host{
 		Id: "h1",
@@ -93,5 +94,66 @@ func TestTerminateUnknownHosts(t *testing.T) {
 	require.NoError(t, env.Configure(ctx))
 	j := NewCloudHostReadyJob(env, "id").(*cloudHostReadyJob)
 	awsErr := "error getting host statuses for providers: error describing instances: after 10 retries, operation failed: InvalidInstanceID.NotFound: The instance IDs 'h1, h2' do not exist"
-	assert.NoError(t, j.terminateUnknownHosts(ctx, awsErr))
+	assert.NoError(t, j.terminateUnknownHostsFromAWSError(ctx, awsErr))
+}
+
+func TestRouteUnknownInstancesSplitsACombinedError(t *testing.T) {
+	hostKeys := map[string]cloud.ClientKey{
+		"region-1-host": {Provider: evergreen.ProviderNameMock, Region: "region-1"},
+		"region-2-host": {Provider: evergreen.ProviderNameMock, Region: "region-2"},
+	}
+
+	// A single batched AWS error naming instances from both regions at
+	// once, as AWS can return when a describe call spans regions.
+	combinedErr := "InvalidInstanceID.NotFound: The instance IDs 'region-1-host, region-2-host' do not exist"
+
+	unknown := routeUnknownInstances([]string{combinedErr}, hostKeys)
+
+	assert.Equal(t, []string{"region-1-host"}, unknown[cloud.ClientKey{Provider: evergreen.ProviderNameMock, Region: "region-1"}])
+	assert.Equal(t, []string{"region-2-host"}, unknown[cloud.ClientKey{Provider: evergreen.ProviderNameMock, Region: "region-2"}])
+}
+
+func TestTerminateUnknownHostsRoutesByRegion(t *testing.T) {
+	require.NoError(t, db.ClearCollections(host.Collection))
+	hosts := []host.Host{
+		{
+			Id:       "region-1-host",
+			Provider: evergreen.ProviderNameMock,
+			Distro: distro.Distro{
+				Provider:             evergreen.ProviderNameMock,
+				ProviderSettingsList: []*birch.Document{birch.NewDocument(birch.EC.String("region", "region-1"))},
+			},
+		},
+		{
+			Id:       "region-2-host",
+			Provider: evergreen.ProviderNameMock,
+			Distro: distro.Distro{
+				Provider:             evergreen.ProviderNameMock,
+				ProviderSettingsList: []*birch.Document{birch.NewDocument(birch.EC.String("region", "region-2"))},
+			},
+		},
+	}
+	for _, h := range hosts {
+		require.NoError(t, h.Insert())
+	}
+
+	env := &mock.Environment{}
+	ctx := context.Background()
+	require.NoError(t, env.Configure(ctx))
+	j := NewCloudHostReadyJob(env, "id").(*cloudHostReadyJob)
+
+	// A single combined error naming instances from both regions, routed
+	// via routeUnknownInstances exactly as Run does, rather than a
+	// hand-bucketed map.
+	combinedErr := "InvalidInstanceID.NotFound: The instance IDs 'region-1-host, region-2-host' do not exist"
+	hostKeys := hostClientKeys(groupHostsByRegion(hosts))
+	unknown := routeUnknownInstances([]string{combinedErr}, hostKeys)
+	require.NoError(t, j.terminateUnknownHosts(ctx, unknown))
+
+	found, err := host.Find(db.Query(bson.M{}))
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	for _, h := range found {
+		assert.Equal(t, evergreen.HostTerminated, h.Status)
+	}
 }